@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 )
 
 // CLIOptions holds command-line flags that adjust runtime behavior.
@@ -16,11 +17,29 @@ type CLIOptions struct {
 	Compact        bool
 	Component      string
 	MinLevel       string
+	Vmodule        string
+	Output         string
+	Sink           string
+	Multiline      string
+	DumpContextAt  stringSliceFlag
 	OnlyAnomalies  bool
 	GenerateConfig bool
 	Version        bool
 }
 
+// stringSliceFlag implements flag.Value for a flag that may be repeated,
+// collecting one entry per occurrence (e.g. --dump-context-at a --dump-context-at b).
+type stringSliceFlag []string
+
+func (s *stringSliceFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringSliceFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
 // printInfo displays a compact, nicely formatted info block to stderr.
 func printInfo() {
 	fmt.Fprintln(os.Stderr, "\033[1;36mLogDrift v0.1.0 — Fast log anomaly detection\033[0m")
@@ -38,6 +57,11 @@ func printInfo() {
 	fmt.Fprintln(os.Stderr, "    --compact               Compact output (no anomaly highlights)")
 	fmt.Fprintln(os.Stderr, "    --component string      Filter by component (e.g., 'parser')")
 	fmt.Fprintln(os.Stderr, "    --min-level string      Minimum log level (default: WARN)")
+	fmt.Fprintln(os.Stderr, "    --vmodule string        Per-component verbosity (parser=DEBUG,http.*=INFO)")
+	fmt.Fprintln(os.Stderr, "    --output string         Entry format: text|json|ndjson (default: text)")
+	fmt.Fprintln(os.Stderr, "    --sink string           Destination: stdout|file:/path|syslog://host:514|tcp://host:port")
+	fmt.Fprintln(os.Stderr, "    --multiline string      Assemble multi-line records: java|python|go|kernel")
+	fmt.Fprintln(os.Stderr, "    --dump-context-at value Force-show N lines around a match: pattern[:N] (repeatable)")
 	fmt.Fprintln(os.Stderr, "    --anomalies-only        Show only anomalies")
 	fmt.Fprintln(os.Stderr, "    --generate-config       Generate default config file")
 	fmt.Fprintln(os.Stderr, "    --version               Show version")
@@ -58,6 +82,11 @@ func ParseCLI() *CLIOptions {
 	flag.BoolVar(&opts.Compact, "compact", false, "Compact output (no anomaly highlights)")
 	flag.StringVar(&opts.Component, "component", "", "Filter by component (e.g., 'parser')")
 	flag.StringVar(&opts.MinLevel, "min-level", "WARN", "Minimum log level (DEBUG/INFO/WARN/ERROR/FATAL)")
+	flag.StringVar(&opts.Vmodule, "vmodule", "", "Per-component verbosity overrides (component=level,glob=level)")
+	flag.StringVar(&opts.Output, "output", "", "Entry format: text|json|ndjson (default: text)")
+	flag.StringVar(&opts.Sink, "sink", "", "Destination: stdout|file:/path|syslog://host:514|tcp://host:port")
+	flag.StringVar(&opts.Multiline, "multiline", "", "Assemble multi-line records using a preset: java|python|go|kernel")
+	flag.Var(&opts.DumpContextAt, "dump-context-at", "Force-show N lines around a match: pattern[:N] (repeatable)")
 	flag.BoolVar(&opts.OnlyAnomalies, "anomalies-only", false, "Show only anomalies")
 	flag.BoolVar(&opts.GenerateConfig, "generate-config", false, "Generate default config file")
 	flag.BoolVar(&opts.Version, "version", false, "Show version")
@@ -109,6 +138,26 @@ func (o *CLIOptions) ApplyToConfig(cfg *Config) {
 	if o.MinLevel != "" {
 		cfg.MinLevel = o.MinLevel
 	}
+
+	if o.Vmodule != "" {
+		cfg.Vmodule = o.Vmodule
+	}
+
+	if o.Output != "" {
+		cfg.OutputFormat = o.Output
+	}
+
+	if o.Sink != "" {
+		cfg.Sink = o.Sink
+	}
+
+	if o.Multiline != "" {
+		cfg.Multiline = o.Multiline
+	}
+
+	if len(o.DumpContextAt) > 0 {
+		cfg.DumpContextAt = o.DumpContextAt
+	}
 }
 
 // HandleSpecialCommands processes flags that should cause immediate output and exit.
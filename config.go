@@ -2,9 +2,12 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
+	"path"
 	"regexp"
 	"slices"
+	"strings"
 )
 
 // Config holds all user-configurable options for logdrift.
@@ -30,6 +33,44 @@ type Config struct {
 	IncludeComponents []string `json:"include_components"` // If set, only these components are shown
 	ExcludeComponents []string `json:"exclude_components"` // Components to always hide
 	MinLevel          string   `json:"min_level"`          // Minimum level to display (e.g., "WARN")
+
+	// Input
+	InputFormat string `json:"input_format"` // Line format: auto|json|logfmt|syslog|gelf|text
+	Multiline   string `json:"multiline"`    // Multi-line assembly preset: ""|java|python|go|kernel
+
+	// Output
+	OutputFormat string `json:"output_format"` // Entry format: text|json|ndjson
+	Sink         string `json:"sink"`          // Destination: stdout|file:/path|syslog://host:514|tcp://host:port
+
+	// Drift detection
+	DriftDetection       bool    `json:"drift_detection"`        // Enable windowed EWMA drift detection
+	DriftWindowSeconds   int     `json:"drift_window_seconds"`   // Rolling window size in seconds
+	DriftHalflifeSeconds float64 `json:"drift_halflife_seconds"` // EWMA decay halflife in seconds
+	DriftK               float64 `json:"drift_k"`                // Stddev multiplier for the drift threshold
+	DriftWarmupWindows   int     `json:"drift_warmup_windows"`   // Windows to observe before drift can fire
+
+	// Vmodule is a glog-style per-component verbosity override, e.g.
+	// "parser=DEBUG,http.*=INFO,auth=ERROR". Overrides MinLevel inside
+	// ShouldShow for components it matches; see CompileVmodule.
+	Vmodule string `json:"vmodule"`
+
+	// DumpContextAt is a set of glog-style "-log_backtrace_at" triggers:
+	// each entry is "pattern[:N]" (N defaults to defaultDumpContextLines).
+	// When a line matches pattern, the N lines immediately before and
+	// after it are force-shown, bypassing MinLevel/component filters. Each
+	// is emitted as its own entry; they are not merged into one record,
+	// and the preceding lines are emitted from a separate goroutine, so
+	// they can appear after the matching line. See ContextDumper.
+	DumpContextAt []string `json:"dump_context_at"`
+
+	vmoduleRules []vmoduleRule // compiled from Vmodule by CompileVmodule; not part of the JSON schema
+}
+
+// vmoduleRule is one compiled "component=level" or "glob=level" entry.
+type vmoduleRule struct {
+	pattern string
+	level   LogLevel
+	isGlob  bool
 }
 
 // DefaultConfig returns sensible defaults so users get useful results without a config file.
@@ -64,6 +105,22 @@ func DefaultConfig() *Config {
 		CompactOutput: false,
 
 		MinLevel: "WARN", // conservative default to reduce noise
+
+		InputFormat: "auto", // sniff JSON/logfmt/syslog per line, fall back to text heuristics
+		Multiline:   "",     // no multi-line record assembly by default
+
+		OutputFormat: "text",   // human-readable colored output by default
+		Sink:         "stdout", // print to the terminal by default
+
+		DriftDetection:       true, // windowed EWMA drift detection on by default
+		DriftWindowSeconds:   60,   // 60s rolling window
+		DriftHalflifeSeconds: 300,  // decay over ~5 windows
+		DriftK:               3,    // flag when rate > baseline + 3*stddev
+		DriftWarmupWindows:   3,    // no drift events during the first 3 windows
+
+		Vmodule: "", // no per-component overrides by default
+
+		DumpContextAt: nil, // no backtrace-at triggers by default
 	}
 }
 
@@ -139,17 +196,80 @@ func (c *Config) CompilePatterns() ([]*regexp.Regexp, []*regexp.Regexp, []*regex
 	return errorRegexes, slowRegexes, suspiciousRegexes, nil
 }
 
+// CompileVmodule parses the Vmodule field ("component=level,glob=level")
+// into a compiled matcher. Call it once after CLI overrides are applied
+// and before ShouldShow is used for filtering.
+func (c *Config) CompileVmodule() error {
+	c.vmoduleRules = nil
+	if c.Vmodule == "" {
+		return nil
+	}
+
+	for _, entry := range strings.Split(c.Vmodule, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid vmodule entry %q: expected component=level", entry)
+		}
+
+		pattern := strings.TrimSpace(parts[0])
+		c.vmoduleRules = append(c.vmoduleRules, vmoduleRule{
+			pattern: pattern,
+			level:   parseLevelString(strings.TrimSpace(parts[1])),
+			isGlob:  strings.ContainsAny(pattern, "*?"),
+		})
+	}
+
+	return nil
+}
+
+// vmoduleLevel returns the MinLevel override for component, if any rule
+// matches. An exact match always wins; otherwise the most specific
+// (longest) matching glob pattern wins.
+func (c *Config) vmoduleLevel(component string) (LogLevel, bool) {
+	for _, rule := range c.vmoduleRules {
+		if !rule.isGlob && rule.pattern == component {
+			return rule.level, true
+		}
+	}
+
+	best := -1
+	var bestLevel LogLevel
+	for _, rule := range c.vmoduleRules {
+		if !rule.isGlob {
+			continue
+		}
+		if matched, _ := path.Match(rule.pattern, component); matched && len(rule.pattern) > best {
+			best = len(rule.pattern)
+			bestLevel = rule.level
+		}
+	}
+	if best >= 0 {
+		return bestLevel, true
+	}
+
+	return 0, false
+}
+
 // ShouldShow decides whether a log entry should be displayed based on level and component filters.
 func (c *Config) ShouldShow(entry LogEntry) bool {
-	// Check minimum level
+	// Check component filters. entry.Component is populated by the active
+	// LineParser (exact for structured formats, regex-extracted for text).
+	component := entry.Component
+
+	// Check minimum level, allowing Vmodule to override it per component.
 	minLevel := c.parseMinLevel()
+	if lvl, ok := c.vmoduleLevel(component); ok {
+		minLevel = lvl
+	}
 	if entry.Level < minLevel {
 		return false
 	}
 
-	// Check component filters
-	component := extractComponent(entry.Raw)
-
 	// If IncludeComponents is non-empty, only show listed components
 	if len(c.IncludeComponents) > 0 {
 		found := slices.Contains(c.IncludeComponents, component)
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// defaultDumpContextLines is N when a --dump-context-at spec omits ":N".
+const defaultDumpContextLines = 5
+
+// contextRule is one compiled "--dump-context-at pattern[:N]" entry.
+type contextRule struct {
+	pattern *regexp.Regexp
+	n       int
+}
+
+// parseContextSpec splits "pattern[:N]" into its regex and context line
+// count, defaulting N to defaultDumpContextLines when omitted.
+func parseContextSpec(spec string) (pattern string, n int) {
+	pattern, n = spec, defaultDumpContextLines
+	if idx := strings.LastIndex(spec, ":"); idx > 0 {
+		if parsed, err := strconv.Atoi(spec[idx+1:]); err == nil {
+			pattern, n = spec[:idx], parsed
+		}
+	}
+	return pattern, n
+}
+
+// ringEntry pairs a buffered LogEntry with whether it was already emitted
+// (printed or submitted to a sink) the first time it was processed, so a
+// later backfill can skip it instead of emitting it a second time.
+type ringEntry struct {
+	entry LogEntry
+	shown bool
+}
+
+// ringBuffer holds the most recently observed LogEntry values so a later
+// trigger match can recover the lines immediately preceding it.
+type ringBuffer struct {
+	entries []ringEntry
+	next    int
+	filled  bool
+}
+
+func newRingBuffer(size int) *ringBuffer {
+	if size < 1 {
+		size = 1
+	}
+	return &ringBuffer{entries: make([]ringEntry, size)}
+}
+
+func (r *ringBuffer) push(entry LogEntry) {
+	r.entries[r.next] = ringEntry{entry: entry}
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.filled = true
+	}
+}
+
+// markLastShown records whether the most recently pushed entry was already
+// emitted by the caller. It must be called once per push, after the caller
+// has decided whether to show that entry, and before the next push.
+func (r *ringBuffer) markLastShown(shown bool) {
+	size := len(r.entries)
+	r.entries[(r.next-1+size)%size].shown = shown
+}
+
+// last returns up to n of the most recently pushed entries not already
+// shown, oldest first.
+func (r *ringBuffer) last(n int) []LogEntry {
+	size := len(r.entries)
+	available := size
+	if !r.filled {
+		available = r.next
+	}
+	if n > available {
+		n = available
+	}
+
+	out := make([]LogEntry, 0, n)
+	for i := 0; i < n; i++ {
+		re := r.entries[(r.next-n+i+size)%size]
+		if re.shown {
+			continue
+		}
+		out = append(out, re.entry)
+	}
+	return out
+}
+
+// ContextDumper implements --dump-context-at: when a line matches one of
+// its trigger patterns, the N lines immediately before and after the
+// match are force-shown as surrounding evidence, bypassing whatever
+// level/component filters would otherwise hide them (e.g. DEBUG lines
+// hidden by MinLevel=WARN around a FATAL/panic).
+type ContextDumper struct {
+	rules     []contextRule
+	ring      *ringBuffer
+	countdown int
+
+	preceding chan LogEntry // backfilled "before" context, drained by main()
+}
+
+// NewContextDumper compiles the --dump-context-at specs ("pattern[:N]",
+// repeatable).
+func NewContextDumper(specs []string) (*ContextDumper, error) {
+	rules := make([]contextRule, 0, len(specs))
+	maxN := defaultDumpContextLines
+
+	for _, spec := range specs {
+		patternStr, n := parseContextSpec(spec)
+		re, err := regexp.Compile(patternStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dump-context-at pattern %q: %w", spec, err)
+		}
+		rules = append(rules, contextRule{pattern: re, n: n})
+		if n > maxN {
+			maxN = n
+		}
+	}
+
+	return &ContextDumper{
+		rules:     rules,
+		ring:      newRingBuffer(maxN),
+		preceding: make(chan LogEntry, 256),
+	}, nil
+}
+
+// Preceding returns the channel of backfilled "before" context entries.
+func (cd *ContextDumper) Preceding() <-chan LogEntry {
+	return cd.preceding
+}
+
+// Close signals that no more entries will be observed, letting a
+// Preceding() consumer's range loop end.
+func (cd *ContextDumper) Close() {
+	close(cd.preceding)
+}
+
+// MarkLastShown records whether the entry most recently passed to Observe
+// was already emitted by the caller (printed or submitted to a sink). The
+// caller makes that decision after Observe returns, so this must be called
+// once per Observe call, before the next one, to keep a later backfill from
+// re-emitting a line that was already shown.
+func (cd *ContextDumper) MarkLastShown(shown bool) {
+	cd.ring.markLastShown(shown)
+}
+
+// Observe feeds entry through the ring buffer and trigger patterns. It
+// reports whether entry itself should be force-shown (because it matched,
+// or falls within the post-match countdown window), and, when entry
+// matches, backfills those of the N preceding entries not already shown
+// onto Preceding().
+func (cd *ContextDumper) Observe(entry LogEntry) (forceShow bool) {
+	matchedN := 0
+	for _, rule := range cd.rules {
+		if rule.n > matchedN && rule.pattern.MatchString(entry.Raw) {
+			matchedN = rule.n
+		}
+	}
+
+	if matchedN > 0 {
+		for _, prior := range cd.ring.last(matchedN) {
+			prior.ForceShow = true
+			select {
+			case cd.preceding <- prior:
+			default:
+				// Backfill channel is full; drop rather than block ParseLine.
+			}
+		}
+		if matchedN > cd.countdown {
+			cd.countdown = matchedN
+		}
+		forceShow = true
+	} else if cd.countdown > 0 {
+		cd.countdown--
+		forceShow = true
+	}
+
+	cd.ring.push(entry)
+	return forceShow
+}
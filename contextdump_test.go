@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+// TestContextDumperSkipsAlreadyShownBackfill reproduces the WARN -> quiet
+// INFO -> FATAL "TRIGGER" sequence from the bug report: the WARN line was
+// already shown on its own pass, so it must not be backfilled a second time
+// when a later trigger's N-preceding window includes it.
+func TestContextDumperSkipsAlreadyShownBackfill(t *testing.T) {
+	cd, err := NewContextDumper([]string{"TRIGGER:2"})
+	if err != nil {
+		t.Fatalf("NewContextDumper: %v", err)
+	}
+	defer cd.Close()
+
+	feed := func(raw string, shown bool) {
+		entry := LogEntry{Raw: raw}
+		entry.ForceShow = cd.Observe(entry)
+		cd.MarkLastShown(shown)
+	}
+
+	feed("WARN something looked off", true)   // shown the first time through
+	feed("INFO quiet line", false)             // filtered out the first time
+	feed("FATAL TRIGGER: everything is on fire", true)
+
+	var backfilled []string
+	for {
+		select {
+		case entry := <-cd.Preceding():
+			backfilled = append(backfilled, entry.Raw)
+			continue
+		default:
+		}
+		break
+	}
+
+	for _, raw := range backfilled {
+		if raw == "WARN something looked off" {
+			t.Errorf("backfill re-emitted an already-shown entry: %q", raw)
+		}
+	}
+	if len(backfilled) != 1 || backfilled[0] != "INFO quiet line" {
+		t.Errorf("backfilled = %v, want only the previously-hidden INFO line", backfilled)
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// DriftEvent is a windowed statistical anomaly, distinct from a single-line
+// LogEntry anomaly: it fires when a component/pattern's rate over the
+// current window strays too far from its learned baseline.
+type DriftEvent struct {
+	Timestamp time.Time // when the window closed
+	Component string    // component the rate was tracked for
+	Pattern   string    // "error", "warn", or a LogEntry.MatchedPatterns category
+	Observed  float64   // this window's rate, in events/sec
+	Expected  float64   // EWMA baseline rate, in events/sec
+	StdDev    float64   // EWMA standard deviation of the rate
+	ZScore    float64   // (Observed - Expected) / max(StdDev, driftMinStddev)
+}
+
+// driftCounter tracks one component/pattern pair. Its EWMA mean and
+// variance are updated once per window tick, keeping memory O(1) per
+// counter regardless of how long the detector has been running.
+type driftCounter struct {
+	component   string
+	pattern     string
+	windowCount int     // matches seen in the window currently accumulating
+	windowsSeen int     // windows closed so far, gates the warm-up period
+	mean        float64 // EWMA baseline rate
+	m2          float64 // EWMA variance accumulator
+}
+
+func (c *driftCounter) stddev() float64 {
+	if c.m2 <= 0 {
+		return 0
+	}
+	return math.Sqrt(c.m2)
+}
+
+// driftMinStddev floors the stddev used for the breach check and z-score so
+// a counter whose baseline has been flat (most commonly a true zero
+// baseline, e.g. a component that's never errored) can still register
+// drift the first time its rate moves off that baseline, instead of the
+// "stddev > 0" guard suppressing detection forever.
+const driftMinStddev = 1e-6
+
+// DriftDetector maintains per-component/per-pattern EWMA baselines over a
+// rolling time window and emits a DriftEvent when a window's rate exceeds
+// baseline + k*stddev. Ticks are driven by a background goroutine rather
+// than by line arrival, so a quiet period still registers as a return to
+// baseline instead of freezing the baseline at its last busy value.
+type DriftDetector struct {
+	window   time.Duration
+	halflife time.Duration
+	k        float64
+	warmup   int
+
+	mu       sync.Mutex
+	counters map[string]*driftCounter
+
+	events chan DriftEvent
+	stop   chan struct{}
+}
+
+// NewDriftDetector builds a detector from the drift-related Config fields.
+func NewDriftDetector(cfg *Config) *DriftDetector {
+	return &DriftDetector{
+		window:   time.Duration(cfg.DriftWindowSeconds) * time.Second,
+		halflife: time.Duration(cfg.DriftHalflifeSeconds) * time.Second,
+		k:        cfg.DriftK,
+		warmup:   cfg.DriftWarmupWindows,
+		counters: make(map[string]*driftCounter),
+		events:   make(chan DriftEvent, 64),
+		stop:     make(chan struct{}),
+	}
+}
+
+// Record counts one occurrence of pattern for component within the window
+// currently accumulating. Called synchronously from AnomalyDetector.ParseLine.
+func (d *DriftDetector) Record(component, pattern string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := component + "\x00" + pattern
+	c, ok := d.counters[key]
+	if !ok {
+		c = &driftCounter{component: component, pattern: pattern}
+		d.counters[key] = c
+	}
+	c.windowCount++
+}
+
+// Events returns the channel DriftEvents are published on. It is closed
+// once Stop has fully drained the ticker goroutine.
+func (d *DriftDetector) Events() <-chan DriftEvent {
+	return d.events
+}
+
+// Run drives the rolling window on a fixed ticker and must run in its own
+// goroutine. It returns once Stop is called.
+func (d *DriftDetector) Run() {
+	defer close(d.events)
+
+	ticker := time.NewTicker(d.window)
+	defer ticker.Stop()
+
+	last := time.Now()
+	for {
+		select {
+		case now := <-ticker.C:
+			d.tick(now.Sub(last), now)
+			last = now
+		case <-d.stop:
+			return
+		}
+	}
+}
+
+// Stop halts the background ticker goroutine; Events() closes once it exits.
+func (d *DriftDetector) Stop() {
+	close(d.stop)
+}
+
+// tick closes out the current window: it checks each counter's rate
+// against its baseline (emitting a DriftEvent on breach), then folds the
+// window into the EWMA baseline and resets the count for the next window.
+func (d *DriftDetector) tick(dt time.Duration, now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	windowSeconds := dt.Seconds()
+	if windowSeconds <= 0 {
+		windowSeconds = d.window.Seconds()
+	}
+	// alpha = 1 - exp(-dt/halflife): decay scaled by elapsed time rather
+	// than a fixed weight, so a delayed tick doesn't skew the baseline.
+	alpha := 1 - math.Exp(-dt.Seconds()/d.halflife.Seconds())
+
+	for _, c := range d.counters {
+		rate := float64(c.windowCount) / windowSeconds
+
+		if c.windowsSeen >= d.warmup {
+			stddev := c.stddev()
+			effectiveStddev := stddev
+			if effectiveStddev < driftMinStddev {
+				effectiveStddev = driftMinStddev
+			}
+			if rate > c.mean+d.k*effectiveStddev {
+				event := DriftEvent{
+					Timestamp: now,
+					Component: c.component,
+					Pattern:   c.pattern,
+					Observed:  rate,
+					Expected:  c.mean,
+					StdDev:    stddev,
+					ZScore:    (rate - c.mean) / effectiveStddev,
+				}
+				select {
+				case d.events <- event:
+				default:
+					// Consumer isn't keeping up; drop rather than stall the ticker.
+				}
+			}
+		}
+
+		// Welford-style incremental combine, adapted for exponential decay:
+		// mean and variance update from O(1) state, no window history kept.
+		delta := rate - c.mean
+		incr := alpha * delta
+		c.mean += incr
+		c.m2 = (1 - alpha) * (c.m2 + delta*incr)
+
+		c.windowsSeen++
+		c.windowCount = 0
+	}
+}
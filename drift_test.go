@@ -0,0 +1,101 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// TestDriftDetectorTickCounting verifies that tick folds the accumulated
+// windowCount into the EWMA baseline and resets it for the next window,
+// regardless of how many distinct Record calls contributed to it.
+func TestDriftDetectorTickCounting(t *testing.T) {
+	d := &DriftDetector{
+		window:   60 * time.Second,
+		halflife: 300 * time.Second,
+		k:        3,
+		warmup:   1,
+		counters: make(map[string]*driftCounter),
+	}
+
+	d.Record("api", "error")
+	d.Record("api", "error")
+	d.Record("api", "error")
+
+	c := d.counters["api\x00error"]
+	if c.windowCount != 3 {
+		t.Fatalf("windowCount before tick = %d, want 3", c.windowCount)
+	}
+
+	d.tick(60*time.Second, time.Now())
+
+	if c.windowCount != 0 {
+		t.Errorf("windowCount after tick = %d, want 0 (tick must reset it for the next window)", c.windowCount)
+	}
+	if c.windowsSeen != 1 {
+		t.Errorf("windowsSeen after tick = %d, want 1", c.windowsSeen)
+	}
+	if c.mean <= 0 {
+		t.Errorf("mean after first tick = %v, want > 0 (should move toward the observed rate)", c.mean)
+	}
+}
+
+// TestDriftDetectorTickWarmup verifies no DriftEvent fires until warmup
+// windows have elapsed, even when a window's rate is wildly elevated.
+func TestDriftDetectorTickWarmup(t *testing.T) {
+	d := &DriftDetector{
+		window:   60 * time.Second,
+		halflife: 300 * time.Second,
+		k:        3,
+		warmup:   2,
+		counters: make(map[string]*driftCounter),
+		events:   make(chan DriftEvent, 8),
+	}
+
+	d.Record("api", "error")
+	d.tick(60*time.Second, time.Now())
+
+	select {
+	case ev := <-d.events:
+		t.Fatalf("unexpected drift event during warm-up: %+v", ev)
+	default:
+	}
+
+	if d.counters["api\x00error"].windowsSeen != 1 {
+		t.Fatalf("windowsSeen = %d, want 1 after one tick", d.counters["api\x00error"].windowsSeen)
+	}
+}
+
+// TestDriftDetectorTickZeroBaselineSpike reproduces the "component that
+// never errors suddenly starts erroring" case: two quiet warm-up windows
+// (rate 0, so stddev stays exactly 0) followed by a window with a real
+// spike must still emit a DriftEvent, instead of the zero stddev
+// permanently suppressing detection for that counter.
+func TestDriftDetectorTickZeroBaselineSpike(t *testing.T) {
+	d := &DriftDetector{
+		window:   60 * time.Second,
+		halflife: 300 * time.Second,
+		k:        3,
+		warmup:   2,
+		counters: make(map[string]*driftCounter),
+		events:   make(chan DriftEvent, 8),
+	}
+
+	d.counters["api\x00error"] = &driftCounter{component: "api", pattern: "error"}
+
+	d.tick(60*time.Second, time.Now()) // quiet window 1
+	d.tick(60*time.Second, time.Now()) // quiet window 2
+
+	for i := 0; i < 100; i++ {
+		d.Record("api", "error")
+	}
+	d.tick(60*time.Second, time.Now()) // the spike
+
+	select {
+	case ev := <-d.events:
+		if ev.Component != "api" || ev.Pattern != "error" {
+			t.Errorf("unexpected event: %+v", ev)
+		}
+	default:
+		t.Fatal("expected a DriftEvent for a spike off a zero baseline, got none")
+	}
+}
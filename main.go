@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -22,16 +23,21 @@ const (
 
 // LogEntry represents a parsed log line and detection metadata
 type LogEntry struct {
-	Timestamp time.Time // time when the line was processed
-	Level     LogLevel  // inferred severity
-	Message   string    // trimmed log message
-	Raw       string    // original line text
-	IsAnomaly bool      // flagged by detector
-	Score     int       // anomaly score (higher = more suspicious)
+	Timestamp       time.Time         // time when the line was processed (or parsed from structured input)
+	Level           LogLevel          // inferred or parsed severity
+	Component       string            // component/service name, parsed or extracted
+	Message         string            // trimmed log message
+	Fields          map[string]string // structured key/value fields; nil for unstructured text lines
+	Raw             string            // original line text
+	IsAnomaly       bool              // flagged by detector
+	Score           int               // anomaly score (higher = more suspicious)
+	MatchedPatterns []string          // which pattern categories matched ("error", "slow", "suspicious")
+	ForceShow       bool              // bypasses ShouldShow/level filtering; set by --dump-context-at
 }
 
 // AnomalyDetector holds compiled patterns, running counters, and config
 type AnomalyDetector struct {
+	parser        LineParser       // decodes raw lines per cfg.InputFormat
 	errorPatterns []*regexp.Regexp // compiled error regexes
 	slowPatterns  []*regexp.Regexp // compiled slow/latency regexes
 	suspiciousIPs []*regexp.Regexp // compiled suspicious activity regexes
@@ -42,6 +48,8 @@ type AnomalyDetector struct {
 	anomalyCount  int              // flagged anomalies
 	startTime     time.Time        // detector start time for stats
 	config        *Config          // active configuration
+	drift         *DriftDetector   // windowed EWMA drift detector, nil when disabled
+	contextDumper *ContextDumper   // --dump-context-at handler, nil when unconfigured
 }
 
 // NewDetector initializes the anomaly detector with default config
@@ -61,7 +69,24 @@ func NewDetectorWithConfig(cfg *Config) *AnomalyDetector {
 		suspiciousRegexes = []*regexp.Regexp{}
 	}
 
+	var drift *DriftDetector
+	if cfg.DriftDetection {
+		drift = NewDriftDetector(cfg)
+		go drift.Run()
+	}
+
+	var contextDumper *ContextDumper
+	if len(cfg.DumpContextAt) > 0 {
+		var err error
+		contextDumper, err = NewContextDumper(cfg.DumpContextAt)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: invalid --dump-context-at config: %v\n", err)
+			contextDumper = nil
+		}
+	}
+
 	return &AnomalyDetector{
+		parser:        NewLineParser(cfg.InputFormat),
 		errorPatterns: errorRegexes,
 		slowPatterns:  slowRegexes,
 		suspiciousIPs: suspiciousRegexes,
@@ -77,8 +102,52 @@ func NewDetectorWithConfig(cfg *Config) *AnomalyDetector {
 			"invalid",
 			"corrupt",
 		},
-		startTime: time.Now(),
-		config:    cfg,
+		startTime:     time.Now(),
+		config:        cfg,
+		drift:         drift,
+		contextDumper: contextDumper,
+	}
+}
+
+// DriftEvents returns the channel of windowed drift events, or nil when
+// drift detection is disabled; callers must check cfg.DriftDetection
+// before ranging over it.
+func (d *AnomalyDetector) DriftEvents() <-chan DriftEvent {
+	if d.drift == nil {
+		return nil
+	}
+	return d.drift.Events()
+}
+
+// ContextDumpEvents returns the channel of --dump-context-at "before"
+// backfill entries, or nil when no --dump-context-at rules are configured
+// or they failed to compile; callers must check for nil before ranging
+// over it.
+func (d *AnomalyDetector) ContextDumpEvents() <-chan LogEntry {
+	if d.contextDumper == nil {
+		return nil
+	}
+	return d.contextDumper.Preceding()
+}
+
+// MarkShown records whether the entry most recently returned by ParseLine
+// was emitted by the caller, so a later --dump-context-at backfill doesn't
+// re-emit a line that was already shown the first time it was processed.
+// Callers must call this once per ParseLine call, before the next one.
+func (d *AnomalyDetector) MarkShown(shown bool) {
+	if d.contextDumper != nil {
+		d.contextDumper.MarkLastShown(shown)
+	}
+}
+
+// Close stops the background drift-detection goroutine, if any, and closes
+// the context-dump backfill channel, if any.
+func (d *AnomalyDetector) Close() {
+	if d.drift != nil {
+		d.drift.Stop()
+	}
+	if d.contextDumper != nil {
+		d.contextDumper.Close()
 	}
 }
 
@@ -87,81 +156,78 @@ func NewDetectorWithConfig(cfg *Config) *AnomalyDetector {
 func (d *AnomalyDetector) ParseLine(line string) LogEntry {
 	d.lineCount++
 
+	parsed := d.parser.Parse(line)
+
 	entry := LogEntry{
 		Raw:       line,
-		Timestamp: time.Now(),
+		Timestamp: parsed.Timestamp,
+		Level:     parsed.Level,
+		Component: parsed.Component,
+		Message:   parsed.Message,
+		Fields:    parsed.Fields,
 		Score:     0,
 	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	if entry.Message == "" {
+		entry.Message = strings.TrimSpace(line)
+	}
 
-	lineLower := strings.ToLower(line)
-
-	// Prefer structured level tokens like "ERROR [component]" or similar.
-	levelPattern := regexp.MustCompile(`(?i)\b(FATAL|CRITICAL|ERROR|WARN|INFO|DEBUG)\s+\[`)
-	if matches := levelPattern.FindStringSubmatch(line); len(matches) > 1 {
-		levelStr := strings.ToUpper(matches[1])
-		switch levelStr {
-		case "FATAL", "CRITICAL":
-			entry.Level = FATAL
-			entry.Score += 100
-			d.errorCount++
-		case "ERROR":
-			entry.Level = ERROR
-			entry.Score += 50
-			d.errorCount++
-		case "WARN":
-			entry.Level = WARN
-			entry.Score += 20
-			d.warnCount++
-		case "INFO":
-			entry.Level = INFO
-		default:
-			entry.Level = DEBUG
-		}
-	} else {
-		// Fallback: simple keyword checks when no structured level found.
-		switch {
-		case strings.Contains(lineLower, "fatal") || strings.Contains(lineLower, "critical"):
-			entry.Level = FATAL
-			entry.Score += 100
-			d.errorCount++
-		case strings.Contains(lineLower, "error") && !strings.Contains(lineLower, "errors=0"):
-			entry.Level = ERROR
-			entry.Score += 50
-			d.errorCount++
-		case strings.Contains(lineLower, "warn"):
-			entry.Level = WARN
-			entry.Score += 20
-			d.warnCount++
-		case strings.Contains(lineLower, "info"):
-			entry.Level = INFO
-		default:
-			entry.Level = DEBUG
-		}
+	// Tally level-based counters and the baseline severity score.
+	isErrorLine := false
+	switch entry.Level {
+	case FATAL:
+		entry.Score += 100
+		d.errorCount++
+		isErrorLine = true
+	case ERROR:
+		entry.Score += 50
+		d.errorCount++
+		isErrorLine = true
+	case WARN:
+		entry.Score += 20
+		d.warnCount++
+		d.recordDrift(entry.Component, "warn")
 	}
 
 	// Increase score and mark anomaly if any error patterns match.
+	matchedError := false
 	for _, pattern := range d.errorPatterns {
 		if pattern.MatchString(line) {
 			entry.Score += 30
 			entry.IsAnomaly = true
+			matchedError = true
 		}
 	}
-
-	// Slow/latency indicators also increase score.
-	for _, pattern := range d.slowPatterns {
-		if pattern.MatchString(line) {
-			entry.Score += 25
-			entry.IsAnomaly = true
-		}
+	if matchedError {
+		entry.MatchedPatterns = append(entry.MatchedPatterns, "error")
+		isErrorLine = true
+	}
+	// Record "error" drift at most once per entry: the level-based check and
+	// the pattern match both key off the same error/exception/panic wording,
+	// so counting both would double the observed rate the EWMA tracks.
+	if isErrorLine {
+		d.recordDrift(entry.Component, "error")
 	}
 
+	// Slow/latency indicators also increase score, preferring an exact
+	// comparison against a parsed numeric field over the regex fallback.
+	d.scoreSlow(&entry, line)
+
 	// Suspicious activity (security/attack indicators) increases score more.
+	matchedSuspicious := false
 	for _, pattern := range d.suspiciousIPs {
 		if pattern.MatchString(line) {
 			entry.Score += 40
 			entry.IsAnomaly = true
+			matchedSuspicious = true
 		}
 	}
+	if matchedSuspicious {
+		entry.MatchedPatterns = append(entry.MatchedPatterns, "suspicious")
+		d.recordDrift(entry.Component, "suspicious")
+	}
 
 	// Final anomaly decision based on configured threshold.
 	if entry.Score >= d.config.AnomalyThreshold {
@@ -169,10 +235,58 @@ func (d *AnomalyDetector) ParseLine(line string) LogEntry {
 		d.anomalyCount++
 	}
 
-	entry.Message = strings.TrimSpace(line)
+	// --dump-context-at: force this entry past filters if it matched a
+	// trigger (or falls within the post-match countdown), and backfill those
+	// of the N preceding entries not already shown onto ContextDumpEvents().
+	if d.contextDumper != nil {
+		entry.ForceShow = d.contextDumper.Observe(entry)
+	}
+
 	return entry
 }
 
+// scoreSlow flags slow/latency anomalies. When the parser gave us a
+// numeric latency field (JSON/logfmt/GELF), compare it exactly against
+// SlowThresholdMs instead of pattern-matching the raw text.
+func (d *AnomalyDetector) scoreSlow(entry *LogEntry, line string) {
+	for _, key := range []string{"latency_ms", "response_time", "duration_ms", "elapsed_ms"} {
+		raw, ok := entry.Fields[key]
+		if !ok {
+			continue
+		}
+		if ms, err := strconv.ParseFloat(raw, 64); err == nil {
+			if ms > float64(d.config.SlowThresholdMs) {
+				entry.Score += 25
+				entry.IsAnomaly = true
+				entry.MatchedPatterns = append(entry.MatchedPatterns, "slow")
+				d.recordDrift(entry.Component, "slow")
+			}
+			return
+		}
+	}
+
+	matchedSlow := false
+	for _, pattern := range d.slowPatterns {
+		if pattern.MatchString(line) {
+			entry.Score += 25
+			entry.IsAnomaly = true
+			matchedSlow = true
+		}
+	}
+	if matchedSlow {
+		entry.MatchedPatterns = append(entry.MatchedPatterns, "slow")
+		d.recordDrift(entry.Component, "slow")
+	}
+}
+
+// recordDrift feeds one occurrence of pattern for component into the
+// rolling-window drift detector, if enabled.
+func (d *AnomalyDetector) recordDrift(component, pattern string) {
+	if d.drift != nil {
+		d.drift.Record(component, pattern)
+	}
+}
+
 // ColorCode returns an ANSI color code for a log level.
 func (l LogLevel) ColorCode() string {
 	switch l {
@@ -285,8 +399,79 @@ func main() {
 	// Apply CLI overrides on top of config file
 	opts.ApplyToConfig(cfg)
 
+	// Compile the (possibly CLI-overridden) Vmodule string into a matcher.
+	if err := cfg.CompileVmodule(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing vmodule: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Create detector with the active configuration
 	detector := NewDetectorWithConfig(cfg)
+
+	// Build the output sink. The default text+stdout combination keeps using
+	// LogEntry.Print directly below to preserve colored/anomaly-highlighted
+	// terminal output; any other combination routes through the sink pipeline.
+	useSink := cfg.OutputFormat != "text" || (cfg.Sink != "" && cfg.Sink != "stdout")
+	sink, err := NewOutputSink(cfg.Sink, cfg.OutputFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating sink: %v\n", err)
+		os.Exit(1)
+	}
+	pipeline := NewSinkPipeline(sink)
+
+	// Drift events always go through the sink pipeline (there's no
+	// LogEntry.Print equivalent for them) regardless of useSink. driftDone
+	// signals once this forwarding loop has drained, so shutdown can close
+	// the pipeline only after the last drift event has been submitted.
+	driftDone := make(chan struct{})
+	if cfg.DriftDetection {
+		go func() {
+			defer close(driftDone)
+			for event := range detector.DriftEvents() {
+				pipeline.SubmitDrift(event)
+			}
+		}()
+	} else {
+		close(driftDone)
+	}
+
+	// --dump-context-at backfill entries also bypass LogEntry.Print's usual
+	// gating; they're already fully-scored entries being replayed, not new
+	// lines, so they go straight to the sink or the terminal. contextDone
+	// mirrors driftDone's drain-before-close handshake.
+	contextDone := make(chan struct{})
+	if contextEvents := detector.ContextDumpEvents(); contextEvents != nil {
+		go func() {
+			defer close(contextDone)
+			for entry := range contextEvents {
+				if useSink {
+					pipeline.Submit(entry)
+				} else {
+					entry.Print(cfg)
+				}
+			}
+		}()
+	} else {
+		close(contextDone)
+	}
+
+	// Build the multi-line assembler, if requested, before ParseLine sees
+	// any lines, so stack traces and kernel oops blocks arrive as one record.
+	var assembler *MultilineAssembler
+	if cfg.Multiline != "" {
+		preset, ok := MultilinePresets[cfg.Multiline]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: unknown multiline preset %q\n", cfg.Multiline)
+			os.Exit(1)
+		}
+		var err error
+		assembler, err = NewMultilineAssembler(preset)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error compiling multiline config: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	scanner := bufio.NewScanner(os.Stdin)
 
 	// Increase buffer size to handle long log lines safely (1MB limit here).
@@ -297,35 +482,70 @@ func main() {
 		fmt.Fprintf(os.Stderr, "\033[1;36mLogDrift v0.1.0 - Monitoring stdin...\033[0m\n\n")
 	}
 
-	// Process each line from stdin
-	for scanner.Scan() {
-		line := scanner.Text()
-		entry := detector.ParseLine(line)
+	// process handles one fully-assembled record (a single line, unless a
+	// multiline preset grouped several into one LogEntry.Raw).
+	process := func(raw string) {
+		entry := detector.ParseLine(raw)
 
-		// Apply config-driven filtering (level/component)
-		if !cfg.ShouldShow(entry) {
-			continue
+		// Apply config-driven filtering (level/component), unless
+		// --dump-context-at forced this entry through as trigger context.
+		if !entry.ForceShow && !cfg.ShouldShow(entry) {
+			detector.MarkShown(false)
+			return
 		}
 
-		// Respect --anomalies-only flag; otherwise show anomalies and WARN+.
-		if opts.OnlyAnomalies {
-			if entry.IsAnomaly {
-				entry.Print(cfg)
-			}
-		} else {
-			// Default: display anomalies and WARN+ messages for visibility.
-			if entry.IsAnomaly || entry.Level >= WARN {
+		// Respect --anomalies-only flag; otherwise show anomalies, WARN+,
+		// and anything forced through by --dump-context-at.
+		show := entry.IsAnomaly || entry.ForceShow
+		if !opts.OnlyAnomalies {
+			show = show || entry.Level >= WARN
+		}
+		detector.MarkShown(show)
+
+		if show {
+			if useSink {
+				pipeline.Submit(entry)
+			} else {
 				entry.Print(cfg)
 			}
 		}
 	}
 
+	if assembler == nil {
+		for scanner.Scan() {
+			process(scanner.Text())
+		}
+	} else {
+		lines := make(chan string)
+		go func() {
+			defer close(lines)
+			for scanner.Scan() {
+				lines <- scanner.Text()
+			}
+		}()
+		RunAssembled(lines, assembler, process)
+	}
+
 	// Handle any scanner errors
 	if err := scanner.Err(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
 		os.Exit(1)
 	}
 
+	// Stop the drift detector and context dumper, and wait for their
+	// forwarding goroutines to drain before closing the pipeline, so no
+	// event is submitted to a closed channel.
+	detector.Close()
+	<-driftDone
+	<-contextDone
+
+	if err := pipeline.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error closing sink: %v\n", err)
+	}
+	if dropped := pipeline.Dropped(); dropped > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: sink back-pressure dropped %d entries\n", dropped)
+	}
+
 	// Print final statistics unless compact output was requested
 	if !cfg.CompactOutput {
 		detector.PrintStats()
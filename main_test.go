@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+// TestParseLineRecordsErrorDriftOnce guards against double-counting: a line
+// that is both FATAL/ERROR level and matches a default error pattern (the
+// common case, since both key off words like "fatal"/"panic"/"error") must
+// only bump the "error" drift counter once per line.
+func TestParseLineRecordsErrorDriftOnce(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.DriftDetection = false // drive the counter directly, without the ticker goroutine
+	d := NewDetectorWithConfig(cfg)
+	d.drift = &DriftDetector{counters: make(map[string]*driftCounter)}
+
+	d.ParseLine("2024-01-01T00:00:00Z FATAL [api] panic: runtime error: nil pointer dereference")
+
+	c := d.drift.counters["api\x00error"]
+	if c == nil {
+		t.Fatalf("expected an \"error\" drift counter for component \"api\"")
+	}
+	if c.windowCount != 1 {
+		t.Errorf("windowCount = %d, want 1 (level and pattern match must not double-count)", c.windowCount)
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MultilineConfig configures how raw stdin lines are assembled into a
+// single record before reaching ParseLine, so stack traces and kernel
+// oops blocks aren't mangled into one entry per line.
+type MultilineConfig struct {
+	StartPattern        string        // optional; restricts which lines are eligible record heads
+	ContinuationPattern string        // lines matching this attach to the in-progress record
+	MaxLines            int           // cap on lines per record
+	FlushTimeout        time.Duration // force-flush a stalled record after this long
+}
+
+// MultilinePresets are built-in MultilineConfigs selectable by name via
+// Config.Multiline, covering JVM/Python/Go traces and Linux kernel oops
+// blocks.
+var MultilinePresets = map[string]MultilineConfig{
+	"java": {
+		ContinuationPattern: `^\s+at\s|^Caused by:|^\s*\.\.\.\s*\d+\s+more`,
+		MaxLines:            200,
+		FlushTimeout:        2 * time.Second,
+	},
+	"python": {
+		StartPattern:        `^Traceback\s\(most recent call last\):`,
+		ContinuationPattern: `^\s+File\s"[^"]+",\sline\s\d+|^\s{4,}\S|^\w+(Error|Exception):`,
+		MaxLines:            200,
+		FlushTimeout:        2 * time.Second,
+	},
+	"go": {
+		StartPattern:        `^(panic:|goroutine\s\d+\s\[)`,
+		ContinuationPattern: `^goroutine\s\d+\s\[|^\s|^\[signal`,
+		MaxLines:            200,
+		FlushTimeout:        2 * time.Second,
+	},
+	"kernel": {
+		StartPattern:        `^\[\s*\d+\.\d+\]\s*(BUG:|WARNING:|Call Trace:|Oops)`,
+		ContinuationPattern: `^\[\s*\d+\.\d+\]\s*(\?\s|\S+\+0x)`,
+		MaxLines:            200,
+		FlushTimeout:        2 * time.Second,
+	},
+}
+
+// multilineRecord is a record in progress: the lines collected so far and
+// whether its head line is eligible to accumulate continuations.
+type multilineRecord struct {
+	lines       []string
+	isMultiline bool
+	lastSeen    time.Time
+}
+
+// MultilineAssembler groups consecutive stdin lines into records by
+// configurable continuation rules, ahead of AnomalyDetector.ParseLine.
+type MultilineAssembler struct {
+	startRe        *regexp.Regexp // nil means any line is an eligible head
+	continuationRe *regexp.Regexp
+	maxLines       int
+	flushTimeout   time.Duration
+
+	mu      sync.Mutex
+	current *multilineRecord
+
+	flushed chan string // records force-completed by FlushTimeout
+	stop    chan struct{}
+}
+
+// NewMultilineAssembler compiles cfg and starts the background goroutine
+// that watches for stalled records.
+func NewMultilineAssembler(cfg MultilineConfig) (*MultilineAssembler, error) {
+	a := &MultilineAssembler{
+		maxLines:     cfg.MaxLines,
+		flushTimeout: cfg.FlushTimeout,
+		flushed:      make(chan string, 16),
+		stop:         make(chan struct{}),
+	}
+
+	if cfg.StartPattern != "" {
+		re, err := regexp.Compile(cfg.StartPattern)
+		if err != nil {
+			return nil, err
+		}
+		a.startRe = re
+	}
+
+	if cfg.ContinuationPattern != "" {
+		re, err := regexp.Compile(cfg.ContinuationPattern)
+		if err != nil {
+			return nil, err
+		}
+		a.continuationRe = re
+	}
+
+	if a.maxLines <= 0 {
+		a.maxLines = 200
+	}
+	if a.flushTimeout <= 0 {
+		a.flushTimeout = 2 * time.Second
+	}
+
+	go a.watchTimeout()
+	return a, nil
+}
+
+// Feed appends line to the record in progress, or starts a new one. It
+// returns the previously completed record's assembled text when line
+// causes a flush (a new head line arrived, or MaxLines was hit).
+func (a *MultilineAssembler) Feed(line string) (string, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.current != nil && a.current.isMultiline && a.continuationRe != nil &&
+		a.continuationRe.MatchString(line) && len(a.current.lines) < a.maxLines {
+		a.current.lines = append(a.current.lines, line)
+		a.current.lastSeen = time.Now()
+		return "", false
+	}
+
+	text, flushed := a.flushLocked()
+
+	a.current = &multilineRecord{
+		lines:       []string{line},
+		isMultiline: a.startRe == nil || a.startRe.MatchString(line),
+		lastSeen:    time.Now(),
+	}
+
+	return text, flushed
+}
+
+// Flush force-completes the in-progress record, if any. Call at EOF so
+// the last record isn't lost.
+func (a *MultilineAssembler) Flush() (string, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.flushLocked()
+}
+
+// flushLocked completes and returns the current record. Caller must hold a.mu.
+func (a *MultilineAssembler) flushLocked() (string, bool) {
+	if a.current == nil || len(a.current.lines) == 0 {
+		a.current = nil
+		return "", false
+	}
+	text := strings.Join(a.current.lines, "\n")
+	a.current = nil
+	return text, true
+}
+
+// Flushed returns the channel of records force-completed by FlushTimeout,
+// for a record whose producer went quiet mid-trace.
+func (a *MultilineAssembler) Flushed() <-chan string {
+	return a.flushed
+}
+
+// watchTimeout force-flushes the in-progress record once it's been idle
+// for longer than flushTimeout, so a hung producer can't buffer it forever.
+func (a *MultilineAssembler) watchTimeout() {
+	ticker := time.NewTicker(a.flushTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			a.mu.Lock()
+			stalled := a.current != nil &&
+				time.Since(a.current.lastSeen) >= a.flushTimeout
+			if !stalled {
+				a.mu.Unlock()
+				continue
+			}
+			text, ok := a.flushLocked()
+			a.mu.Unlock()
+			if ok {
+				select {
+				case a.flushed <- text:
+				default:
+					// Consumer isn't keeping up; drop rather than block the watcher.
+				}
+			}
+		case <-a.stop:
+			return
+		}
+	}
+}
+
+// Stop halts the background timeout-watcher goroutine.
+func (a *MultilineAssembler) Stop() {
+	close(a.stop)
+}
+
+// RunAssembled drains lines (closed at EOF) through assembler, calling
+// process on every completed record - whether completed because a new
+// record started, MaxLines was hit, EOF was reached, or a stalled record
+// hit FlushTimeout.
+func RunAssembled(lines <-chan string, assembler *MultilineAssembler, process func(string)) {
+	defer assembler.Stop()
+
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				if text, flushed := assembler.Flush(); flushed {
+					process(text)
+				}
+				return
+			}
+			if text, flushed := assembler.Feed(line); flushed {
+				process(text)
+			}
+		case text := <-assembler.Flushed():
+			process(text)
+		}
+	}
+}
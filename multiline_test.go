@@ -0,0 +1,127 @@
+package main
+
+import "testing"
+
+// TestMultilinePresetsBoundaries feeds each built-in preset a short stream
+// and checks it lands on the expected record boundaries: genuine traces
+// collapse into one record, unrelated lines around them stay separate.
+func TestMultilinePresetsBoundaries(t *testing.T) {
+	tests := []struct {
+		name    string
+		preset  string
+		lines   []string
+		records []string // expected completed records, in order
+	}{
+		{
+			name:   "python traceback collapses, unrelated indentation does not",
+			preset: "python",
+			lines: []string{
+				"INFO starting request handler",
+				"  nested: value1",
+				"  nested: value2",
+				"INFO another unrelated line",
+			},
+			records: []string{
+				"INFO starting request handler",
+				"  nested: value1",
+				"  nested: value2",
+				"INFO another unrelated line",
+			},
+		},
+		{
+			name:   "python traceback with raise line and exception tail",
+			preset: "python",
+			lines: []string{
+				`Traceback (most recent call last):`,
+				`  File "app.py", line 10, in handler`,
+				`    raise ValueError("bad input")`,
+				`ValueError: bad input`,
+				"INFO request complete",
+			},
+			records: []string{
+				"Traceback (most recent call last):\n" +
+					`  File "app.py", line 10, in handler` + "\n" +
+					`    raise ValueError("bad input")` + "\n" +
+					`ValueError: bad input`,
+				"INFO request complete",
+			},
+		},
+		{
+			name:   "java stack trace collapses",
+			preset: "java",
+			lines: []string{
+				"java.lang.NullPointerException: boom",
+				"\tat com.example.Foo.bar(Foo.java:42)",
+				"\tat com.example.Foo.main(Foo.java:10)",
+				"INFO request complete",
+			},
+			records: []string{
+				"java.lang.NullPointerException: boom\n" +
+					"\tat com.example.Foo.bar(Foo.java:42)\n" +
+					"\tat com.example.Foo.main(Foo.java:10)",
+				"INFO request complete",
+			},
+		},
+		{
+			name:   "go panic collapses contiguous indented frames",
+			preset: "go",
+			lines: []string{
+				"panic: runtime error: index out of range",
+				"goroutine 1 [running]:",
+				"\t/app/main.go:10 +0x20",
+				"INFO request complete",
+			},
+			records: []string{
+				"panic: runtime error: index out of range\n" +
+					"goroutine 1 [running]:\n" +
+					"\t/app/main.go:10 +0x20",
+				"INFO request complete",
+			},
+		},
+		{
+			name:   "kernel oops collapses",
+			preset: "kernel",
+			lines: []string{
+				"[12345.678] BUG: unable to handle page fault",
+				"[12345.679] ? do_page_fault+0x10",
+				"[12345.680] RIP: 0010:my_driver_probe+0x55",
+				"audit: something unrelated",
+			},
+			records: []string{
+				"[12345.678] BUG: unable to handle page fault\n" +
+					"[12345.679] ? do_page_fault+0x10",
+				"[12345.680] RIP: 0010:my_driver_probe+0x55",
+				"audit: something unrelated",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := NewMultilineAssembler(MultilinePresets[tt.preset])
+			if err != nil {
+				t.Fatalf("NewMultilineAssembler(%q): %v", tt.preset, err)
+			}
+			defer a.Stop()
+
+			var got []string
+			for _, line := range tt.lines {
+				if text, flushed := a.Feed(line); flushed {
+					got = append(got, text)
+				}
+			}
+			if text, flushed := a.Flush(); flushed {
+				got = append(got, text)
+			}
+
+			if len(got) != len(tt.records) {
+				t.Fatalf("got %d records, want %d\ngot:  %#v\nwant: %#v", len(got), len(tt.records), got, tt.records)
+			}
+			for i := range got {
+				if got[i] != tt.records[i] {
+					t.Errorf("record %d = %q, want %q", i, got[i], tt.records[i])
+				}
+			}
+		})
+	}
+}
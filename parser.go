@@ -0,0 +1,323 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ParsedLine is the structured result of a LineParser: everything the
+// detector needs to build a LogEntry without re-deriving level/component
+// from the raw text itself.
+type ParsedLine struct {
+	Timestamp time.Time         // zero value means "use time.Now()"
+	Level     LogLevel          // inferred or explicitly parsed severity
+	Component string            // service/logger/component name, empty if none found
+	Message   string            // human-readable message, empty falls back to the raw line
+	Fields    map[string]string // structured key/value fields; nil for unstructured text
+}
+
+// LineParser turns one raw input line into a ParsedLine. Implementations
+// are selected via Config.InputFormat ("auto", "json", "logfmt", "syslog",
+// "gelf", or "text").
+type LineParser interface {
+	Parse(line string) ParsedLine
+}
+
+// NewLineParser returns the LineParser for the given input_format name.
+// Unknown or empty values fall back to auto-detection.
+func NewLineParser(format string) LineParser {
+	switch format {
+	case "json":
+		return jsonParser{}
+	case "logfmt":
+		return logfmtParser{}
+	case "syslog":
+		return syslogParser{}
+	case "gelf":
+		return gelfParser{}
+	case "text":
+		return textParser{}
+	default:
+		return autoParser{}
+	}
+}
+
+var (
+	syslogPriPattern = regexp.MustCompile(`^<\d{1,3}>`)
+	logfmtPairFinder = regexp.MustCompile(`(\w[\w.-]*)=("(?:[^"\\]|\\.)*"|\S+)`)
+)
+
+// DetectFormat sniffs a line's first non-space byte to guess its format,
+// per request: "{" -> JSON, "<pri>" prefix -> syslog, k=v pairs -> logfmt.
+func DetectFormat(line string) string {
+	trimmed := strings.TrimLeft(line, " \t")
+	if trimmed == "" {
+		return "text"
+	}
+
+	switch trimmed[0] {
+	case '{':
+		return "json"
+	case '<':
+		if syslogPriPattern.MatchString(trimmed) {
+			return "syslog"
+		}
+	}
+
+	if logfmtPairFinder.MatchString(trimmed) {
+		return "logfmt"
+	}
+
+	return "text"
+}
+
+// autoParser detects the format of each line independently and delegates
+// to the matching parser. This is the default so mixed-format streams
+// (e.g. app JSON logs interleaved with kernel text) still parse cleanly.
+type autoParser struct{}
+
+func (autoParser) Parse(line string) ParsedLine {
+	return NewLineParser(DetectFormat(line)).Parse(line)
+}
+
+// textParser is the original unstructured heuristic: prefer a structured
+// level token like "ERROR [component]", fall back to keyword sniffing.
+type textParser struct{}
+
+var textLevelPattern = regexp.MustCompile(`(?i)\b(FATAL|CRITICAL|ERROR|WARN|INFO|DEBUG)\s+\[`)
+
+func (textParser) Parse(line string) ParsedLine {
+	lineLower := strings.ToLower(line)
+
+	var level LogLevel
+	if matches := textLevelPattern.FindStringSubmatch(line); len(matches) > 1 {
+		level = parseLevelString(matches[1])
+	} else {
+		switch {
+		case strings.Contains(lineLower, "fatal") || strings.Contains(lineLower, "critical"):
+			level = FATAL
+		case strings.Contains(lineLower, "error") && !strings.Contains(lineLower, "errors=0"):
+			level = ERROR
+		case strings.Contains(lineLower, "warn"):
+			level = WARN
+		case strings.Contains(lineLower, "info"):
+			level = INFO
+		default:
+			level = DEBUG
+		}
+	}
+
+	return ParsedLine{
+		Level:     level,
+		Component: extractComponent(line),
+		Message:   strings.TrimSpace(line),
+	}
+}
+
+// jsonParser decodes structured JSON logs (Elastic/Fluentd/Vector, Docker
+// and Kubernetes container logs, `journalctl -o json`, etc).
+type jsonParser struct{}
+
+func (jsonParser) Parse(line string) ParsedLine {
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &raw); err != nil {
+		// Not actually JSON despite the leading '{'; don't lose the line.
+		return textParser{}.Parse(line)
+	}
+
+	fields := make(map[string]string, len(raw))
+	for key, value := range raw {
+		fields[key] = fmt.Sprint(value)
+	}
+
+	parsed := ParsedLine{
+		Fields:    fields,
+		Level:     parseLevelString(firstMapValue(raw, "level", "severity", "loglevel")),
+		Component: firstMapValue(raw, "component", "logger", "service", "module"),
+		Message:   firstMapValue(raw, "message", "msg", "log"),
+	}
+
+	if ts := firstMapValue(raw, "timestamp", "time", "ts", "@timestamp"); ts != "" {
+		if t, err := time.Parse(time.RFC3339, ts); err == nil {
+			parsed.Timestamp = t
+		}
+	}
+
+	return parsed
+}
+
+// logfmtParser decodes key=value pairs common to Go/Prometheus/etcd logs.
+type logfmtParser struct{}
+
+func (logfmtParser) Parse(line string) ParsedLine {
+	matches := logfmtPairFinder.FindAllStringSubmatch(line, -1)
+	if len(matches) == 0 {
+		return textParser{}.Parse(line)
+	}
+
+	fields := make(map[string]string, len(matches))
+	for _, m := range matches {
+		key, value := m[1], m[2]
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			value = strings.ReplaceAll(value[1:len(value)-1], `\"`, `"`)
+		}
+		fields[key] = value
+	}
+
+	parsed := ParsedLine{
+		Fields:    fields,
+		Level:     parseLevelString(firstFieldValue(fields, "level", "lvl", "severity")),
+		Component: firstFieldValue(fields, "component", "logger", "module", "caller"),
+		Message:   firstFieldValue(fields, "msg", "message"),
+	}
+
+	if ts := firstFieldValue(fields, "ts", "time", "timestamp"); ts != "" {
+		if t, err := time.Parse(time.RFC3339, ts); err == nil {
+			parsed.Timestamp = t
+		}
+	}
+
+	return parsed
+}
+
+// syslogParser decodes RFC5424 syslog: "<PRI>VERSION TIMESTAMP HOSTNAME
+// APP-NAME PROCID MSGID STRUCTURED-DATA MSG".
+type syslogParser struct{}
+
+// STRUCTURED-DATA ("-" or one or more [id k="v" ...] elements) is matched
+// and discarded; a line that omits it is still accepted.
+var syslog5424Pattern = regexp.MustCompile(`^<(\d{1,3})>(\d)\s+(\S+)\s+(\S+)\s+(\S+)\s+(\S+)\s+(\S+)\s+(?:(-|(?:\[(?:[^"\]]|"(?:[^"\\]|\\.)*")*\])+)(?:\s+|$))?(.*)$`)
+
+func (syslogParser) Parse(line string) ParsedLine {
+	m := syslog5424Pattern.FindStringSubmatch(line)
+	if m == nil {
+		return textParser{}.Parse(line)
+	}
+
+	pri := atoiOrZero(m[1])
+	timestamp, _ := time.Parse(time.RFC3339Nano, m[3])
+
+	return ParsedLine{
+		Timestamp: timestamp,
+		Level:     syslogSeverityToLevel(pri % 8),
+		Component: m[5], // APP-NAME
+		Message:   strings.TrimSpace(m[9]),
+		Fields: map[string]string{
+			"pri":      m[1],
+			"version":  m[2],
+			"hostname": m[4],
+			"app_name": m[5],
+			"procid":   m[6],
+			"msgid":    m[7],
+		},
+	}
+}
+
+// gelfParser decodes Graylog Extended Log Format messages: JSON with a
+// numeric syslog-style "level" and a "short_message".
+type gelfParser struct{}
+
+func (gelfParser) Parse(line string) ParsedLine {
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &raw); err != nil {
+		return textParser{}.Parse(line)
+	}
+
+	fields := make(map[string]string, len(raw))
+	for key, value := range raw {
+		fields[key] = fmt.Sprint(value)
+	}
+
+	parsed := ParsedLine{
+		Fields:    fields,
+		Component: firstMapValue(raw, "host", "_component", "facility"),
+		Message:   firstMapValue(raw, "short_message", "full_message"),
+	}
+
+	if level, ok := raw["level"].(float64); ok {
+		parsed.Level = syslogSeverityToLevel(int(level))
+	}
+
+	if ts, ok := raw["timestamp"].(float64); ok {
+		// GELF timestamps carry sub-second precision as a decimal fraction
+		// (e.g. 1700000000.25); truncating to int64 would silently drop it.
+		whole := math.Floor(ts)
+		parsed.Timestamp = time.Unix(int64(whole), int64((ts-whole)*float64(time.Second)))
+	}
+
+	return parsed
+}
+
+// parseLevelString maps common level spellings (JSON/logfmt keys, GELF
+// syslog names) onto LogLevel. Unrecognized values default to DEBUG.
+func parseLevelString(s string) LogLevel {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "FATAL", "CRITICAL", "PANIC":
+		return FATAL
+	case "ERROR", "ERR":
+		return ERROR
+	case "WARN", "WARNING":
+		return WARN
+	case "INFO", "NOTICE":
+		return INFO
+	default:
+		return DEBUG
+	}
+}
+
+// syslogSeverityToLevel maps an RFC5424 numeric severity (0-7) onto LogLevel.
+func syslogSeverityToLevel(severity int) LogLevel {
+	switch {
+	case severity <= 2: // Emergency, Alert, Critical
+		return FATAL
+	case severity == 3: // Error
+		return ERROR
+	case severity == 4: // Warning
+		return WARN
+	case severity == 5 || severity == 6: // Notice, Informational
+		return INFO
+	default: // Debug
+		return DEBUG
+	}
+}
+
+// firstMapValue returns the first non-empty string value found under keys,
+// in order, within a decoded JSON object.
+func firstMapValue(m map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if v, ok := m[key]; ok {
+			if s, ok := v.(string); ok && s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// firstFieldValue returns the first non-empty value found under keys, in
+// order, within an already-flattened field map.
+func firstFieldValue(fields map[string]string, keys ...string) string {
+	for _, key := range keys {
+		if v, ok := fields[key]; ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// atoiOrZero parses a small non-negative integer, returning 0 on failure.
+// Used for syslog PRI values, which are always 0-191 by spec.
+func atoiOrZero(s string) int {
+	n := 0
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return 0
+		}
+		n = n*10 + int(r-'0')
+	}
+	return n
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{`{"level":"error","msg":"boom"}`, "json"},
+		{`<34>1 2024-01-01T00:00:00Z host app - - - boom`, "syslog"},
+		{`level=error msg="boom" component=api`, "logfmt"},
+		{`2024-01-01 ERROR [api] something broke`, "text"},
+		{`  {"level":"info"}`, "json"}, // leading whitespace shouldn't confuse sniffing
+		{``, "text"},
+	}
+
+	for _, tt := range tests {
+		if got := DetectFormat(tt.line); got != tt.want {
+			t.Errorf("DetectFormat(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestJSONParser(t *testing.T) {
+	line := `{"level":"error","component":"api","message":"request failed","timestamp":"2024-01-02T03:04:05Z","latency_ms":250}`
+	parsed := jsonParser{}.Parse(line)
+
+	if parsed.Level != ERROR {
+		t.Errorf("Level = %v, want ERROR", parsed.Level)
+	}
+	if parsed.Component != "api" {
+		t.Errorf("Component = %q, want \"api\"", parsed.Component)
+	}
+	if parsed.Message != "request failed" {
+		t.Errorf("Message = %q, want \"request failed\"", parsed.Message)
+	}
+	if !parsed.Timestamp.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("Timestamp = %v, want 2024-01-02T03:04:05Z", parsed.Timestamp)
+	}
+	if parsed.Fields["latency_ms"] != "250" {
+		t.Errorf("Fields[latency_ms] = %q, want \"250\"", parsed.Fields["latency_ms"])
+	}
+}
+
+func TestJSONParserFallsBackToTextOnInvalidJSON(t *testing.T) {
+	parsed := jsonParser{}.Parse(`{not valid json`)
+	if parsed.Fields != nil {
+		t.Errorf("Fields = %v, want nil (should fall back to textParser)", parsed.Fields)
+	}
+}
+
+func TestLogfmtParser(t *testing.T) {
+	line := `ts=2024-01-02T03:04:05Z level=warn component=auth msg="login failed: \"bad creds\""`
+	parsed := logfmtParser{}.Parse(line)
+
+	if parsed.Level != WARN {
+		t.Errorf("Level = %v, want WARN", parsed.Level)
+	}
+	if parsed.Component != "auth" {
+		t.Errorf("Component = %q, want \"auth\"", parsed.Component)
+	}
+	want := `login failed: "bad creds"`
+	if parsed.Message != want {
+		t.Errorf("Message = %q, want %q", parsed.Message, want)
+	}
+	if !parsed.Timestamp.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("Timestamp = %v, want 2024-01-02T03:04:05Z", parsed.Timestamp)
+	}
+}
+
+func TestLogfmtParserFallsBackToTextWhenNoPairs(t *testing.T) {
+	parsed := logfmtParser{}.Parse("this is just a plain sentence")
+	if parsed.Fields != nil {
+		t.Errorf("Fields = %v, want nil (should fall back to textParser)", parsed.Fields)
+	}
+}
+
+func TestSyslogParserRFC5424(t *testing.T) {
+	line := `<34>1 2024-01-02T03:04:05.000Z myhost myapp 1234 ID47 connection refused`
+	parsed := syslogParser{}.Parse(line)
+
+	if parsed.Level != FATAL { // pri 34 -> severity 2 (Critical)
+		t.Errorf("Level = %v, want FATAL", parsed.Level)
+	}
+	if parsed.Component != "myapp" {
+		t.Errorf("Component = %q, want \"myapp\"", parsed.Component)
+	}
+	if parsed.Message != "connection refused" {
+		t.Errorf("Message = %q, want \"connection refused\"", parsed.Message)
+	}
+	if !parsed.Timestamp.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("Timestamp = %v, want 2024-01-02T03:04:05Z", parsed.Timestamp)
+	}
+}
+
+func TestSyslogParserFallsBackToTextOnMalformedLine(t *testing.T) {
+	parsed := syslogParser{}.Parse("<34>not actually rfc5424")
+	if parsed.Fields != nil {
+		t.Errorf("Fields = %v, want nil (should fall back to textParser)", parsed.Fields)
+	}
+}
+
+func TestGELFParser(t *testing.T) {
+	line := `{"version":"1.1","host":"web1","short_message":"disk full","level":2,"timestamp":1700000000.25}`
+	parsed := gelfParser{}.Parse(line)
+
+	if parsed.Level != FATAL { // GELF level 2 -> syslog Critical
+		t.Errorf("Level = %v, want FATAL", parsed.Level)
+	}
+	if parsed.Component != "web1" {
+		t.Errorf("Component = %q, want \"web1\"", parsed.Component)
+	}
+	if parsed.Message != "disk full" {
+		t.Errorf("Message = %q, want \"disk full\"", parsed.Message)
+	}
+	want := time.Unix(1700000000, 250000000) // sub-second fraction must survive
+	if !parsed.Timestamp.Equal(want) {
+		t.Errorf("Timestamp = %v, want %v", parsed.Timestamp, want)
+	}
+}
+
+func TestParseLevelString(t *testing.T) {
+	tests := map[string]LogLevel{
+		"FATAL":    FATAL,
+		"critical": FATAL,
+		"Panic":    FATAL,
+		"error":    ERROR,
+		"ERR":      ERROR,
+		"warn":     WARN,
+		"WARNING":  WARN,
+		"info":     INFO,
+		"notice":   INFO,
+		"":         DEBUG,
+		"bogus":    DEBUG,
+	}
+	for in, want := range tests {
+		if got := parseLevelString(in); got != want {
+			t.Errorf("parseLevelString(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestSyslogSeverityToLevel(t *testing.T) {
+	tests := map[int]LogLevel{
+		0: FATAL,
+		2: FATAL,
+		3: ERROR,
+		4: WARN,
+		5: INFO,
+		6: INFO,
+		7: DEBUG,
+	}
+	for in, want := range tests {
+		if got := syslogSeverityToLevel(in); got != want {
+			t.Errorf("syslogSeverityToLevel(%d) = %v, want %v", in, got, want)
+		}
+	}
+}
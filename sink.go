@@ -0,0 +1,334 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// OutputRecord is the structured wire form of a LogEntry, used by the
+// json/ndjson output formats so downstream collectors (Vector, Fluent
+// Bit, Loki) can consume LogDrift's output without scraping text.
+type OutputRecord struct {
+	Timestamp       time.Time `json:"ts"`
+	Level           string    `json:"level"`
+	Component       string    `json:"component,omitempty"`
+	Score           int       `json:"score"`
+	IsAnomaly       bool      `json:"is_anomaly"`
+	MatchedPatterns []string  `json:"matched_patterns,omitempty"`
+	Raw             string    `json:"raw"`
+}
+
+// newOutputRecord converts a LogEntry into its wire representation.
+func newOutputRecord(entry LogEntry) OutputRecord {
+	return OutputRecord{
+		Timestamp:       entry.Timestamp,
+		Level:           entry.Level.String(),
+		Component:       entry.Component,
+		Score:           entry.Score,
+		IsAnomaly:       entry.IsAnomaly,
+		MatchedPatterns: entry.MatchedPatterns,
+		Raw:             entry.Raw,
+	}
+}
+
+// DriftRecord is the structured wire form of a DriftEvent.
+type DriftRecord struct {
+	Timestamp time.Time `json:"ts"`
+	Type      string    `json:"type"` // always "drift", distinguishes it from an OutputRecord
+	Component string    `json:"component,omitempty"`
+	Pattern   string    `json:"pattern"`
+	Observed  float64   `json:"observed_rate"`
+	Expected  float64   `json:"expected_rate"`
+	StdDev    float64   `json:"stddev"`
+	ZScore    float64   `json:"z_score"`
+}
+
+func newDriftRecord(event DriftEvent) DriftRecord {
+	return DriftRecord{
+		Timestamp: event.Timestamp,
+		Type:      "drift",
+		Component: event.Component,
+		Pattern:   event.Pattern,
+		Observed:  event.Observed,
+		Expected:  event.Expected,
+		StdDev:    event.StdDev,
+		ZScore:    event.ZScore,
+	}
+}
+
+// OutputSink writes formatted entries somewhere: stdout, a rotating file,
+// or a remote collector over syslog/TCP.
+type OutputSink interface {
+	Write(entry LogEntry) error
+	WriteDrift(event DriftEvent) error
+	Close() error
+}
+
+const (
+	defaultMaxFileBytes int64 = 100 * 1024 * 1024 // 100MB per file, similar to glog's default
+	defaultMaxFiles           = 10                // keep this many rotated files around
+)
+
+// NewOutputSink builds the OutputSink named by spec: "" or "stdout",
+// "file:/path", "syslog://host:514", or "tcp://collector:5000". format
+// controls how each entry is serialized ("text", "json", or "ndjson").
+func NewOutputSink(spec, format string) (OutputSink, error) {
+	if spec == "" || spec == "stdout" {
+		return &writerSink{w: os.Stdout, format: format}, nil
+	}
+
+	if path, ok := strings.CutPrefix(spec, "file:"); ok {
+		return newRotatingFileSink(path, format, defaultMaxFileBytes, defaultMaxFiles)
+	}
+
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sink %q: %w", spec, err)
+	}
+
+	switch u.Scheme {
+	case "syslog":
+		conn, err := net.Dial("udp", u.Host)
+		if err != nil {
+			return nil, fmt.Errorf("dialing syslog sink %q: %w", spec, err)
+		}
+		return &writerSink{w: conn, format: format}, nil
+	case "tcp":
+		conn, err := net.Dial("tcp", u.Host)
+		if err != nil {
+			return nil, fmt.Errorf("dialing tcp sink %q: %w", spec, err)
+		}
+		return &writerSink{w: conn, format: format}, nil
+	default:
+		return nil, fmt.Errorf("unsupported sink %q", spec)
+	}
+}
+
+// writerSink formats each entry as text/json/ndjson and writes it to w.
+type writerSink struct {
+	w      io.Writer
+	format string
+}
+
+func (s *writerSink) Write(entry LogEntry) error {
+	switch s.format {
+	case "json":
+		data, err := json.MarshalIndent(newOutputRecord(entry), "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(s.w, "%s\n", data)
+		return err
+	case "ndjson":
+		data, err := json.Marshal(newOutputRecord(entry))
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(s.w, "%s\n", data)
+		return err
+	default:
+		_, err := fmt.Fprintf(s.w, "[%s] %s\n", entry.Level, entry.Message)
+		return err
+	}
+}
+
+func (s *writerSink) WriteDrift(event DriftEvent) error {
+	switch s.format {
+	case "json":
+		data, err := json.MarshalIndent(newDriftRecord(event), "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(s.w, "%s\n", data)
+		return err
+	case "ndjson":
+		data, err := json.Marshal(newDriftRecord(event))
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(s.w, "%s\n", data)
+		return err
+	default:
+		_, err := fmt.Fprintf(s.w, "DRIFT [%s] pattern=%s observed=%.2f/s expected=%.2f/s z=%.2f\n",
+			event.Component, event.Pattern, event.Observed, event.Expected, event.ZScore)
+		return err
+	}
+}
+
+func (s *writerSink) Close() error {
+	if closer, ok := s.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// rotatingFileSink writes to path, rotating to path.1, path.2, ... once the
+// current file exceeds maxBytes, and pruning beyond maxFiles (glog-style
+// size + count rotation).
+type rotatingFileSink struct {
+	writerSink
+	path     string
+	maxBytes int64
+	maxFiles int
+	written  int64
+}
+
+func newRotatingFileSink(path, format string, maxBytes int64, maxFiles int) (*rotatingFileSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &rotatingFileSink{
+		writerSink: writerSink{w: f, format: format},
+		path:       path,
+		maxBytes:   maxBytes,
+		maxFiles:   maxFiles,
+		written:    info.Size(),
+	}, nil
+}
+
+func (s *rotatingFileSink) Write(entry LogEntry) error {
+	if s.written >= s.maxBytes {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	if err := s.writerSink.Write(entry); err != nil {
+		return err
+	}
+
+	if f, ok := s.writerSink.w.(*os.File); ok {
+		if info, err := f.Stat(); err == nil {
+			s.written = info.Size()
+		}
+	}
+
+	return nil
+}
+
+// rotate closes the current file, shifts path.1..path.N up by one (dropping
+// anything beyond maxFiles), and reopens path fresh.
+func (s *rotatingFileSink) rotate() error {
+	if err := s.writerSink.Close(); err != nil {
+		return err
+	}
+
+	for i := s.maxFiles - 1; i >= 1; i-- {
+		src := fmt.Sprintf("%s.%d", s.path, i)
+		if _, err := os.Stat(src); err == nil {
+			os.Rename(src, fmt.Sprintf("%s.%d", s.path, i+1))
+		}
+	}
+	os.Rename(s.path, s.path+".1")
+	os.Remove(fmt.Sprintf("%s.%d", s.path, s.maxFiles+1))
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	s.writerSink.w = f
+	s.written = 0
+	return nil
+}
+
+// defaultSinkBuffer sizes the channel between ParseLine and the sink so a
+// slow file/network sink doesn't stall bufio.Scanner on the read side.
+const defaultSinkBuffer = 1024
+
+// SinkPipeline decouples ParseLine from a (possibly slow) OutputSink with a
+// non-blocking buffered channel. When the buffer is full, new entries are
+// dropped and counted rather than applying back-pressure to the reader.
+type SinkPipeline struct {
+	sink        OutputSink
+	entries     chan LogEntry
+	driftEvents chan DriftEvent
+	done        chan struct{}
+	dropped     int64
+}
+
+// NewSinkPipeline starts a background goroutine draining entries into sink.
+func NewSinkPipeline(sink OutputSink) *SinkPipeline {
+	p := &SinkPipeline{
+		sink:        sink,
+		entries:     make(chan LogEntry, defaultSinkBuffer),
+		driftEvents: make(chan DriftEvent, defaultSinkBuffer),
+		done:        make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+func (p *SinkPipeline) run() {
+	defer close(p.done)
+	for {
+		select {
+		case entry, ok := <-p.entries:
+			if !ok {
+				p.entries = nil
+				break
+			}
+			if err := p.sink.Write(entry); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing to sink: %v\n", err)
+			}
+		case event, ok := <-p.driftEvents:
+			if !ok {
+				p.driftEvents = nil
+				break
+			}
+			if err := p.sink.WriteDrift(event); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing drift event to sink: %v\n", err)
+			}
+		}
+		if p.entries == nil && p.driftEvents == nil {
+			return
+		}
+	}
+}
+
+// Submit enqueues entry without blocking. If the buffer is full, entry is
+// dropped; see Dropped.
+func (p *SinkPipeline) Submit(entry LogEntry) {
+	select {
+	case p.entries <- entry:
+	default:
+		atomic.AddInt64(&p.dropped, 1)
+	}
+}
+
+// SubmitDrift enqueues a DriftEvent without blocking, dropping it under
+// back-pressure just like Submit.
+func (p *SinkPipeline) SubmitDrift(event DriftEvent) {
+	select {
+	case p.driftEvents <- event:
+	default:
+		atomic.AddInt64(&p.dropped, 1)
+	}
+}
+
+// Dropped returns how many entries/events were discarded due to back-pressure.
+func (p *SinkPipeline) Dropped() int64 {
+	return atomic.LoadInt64(&p.dropped)
+}
+
+// Close drains any buffered entries and closes the underlying sink.
+func (p *SinkPipeline) Close() error {
+	close(p.entries)
+	close(p.driftEvents)
+	<-p.done
+	return p.sink.Close()
+}
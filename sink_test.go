@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriterSinkTextFormat(t *testing.T) {
+	var buf bytes.Buffer
+	s := &writerSink{w: &buf, format: "text"}
+
+	if err := s.Write(LogEntry{Level: ERROR, Message: "boom"}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	want := "[ERROR] boom\n"
+	if buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriterSinkNDJSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	s := &writerSink{w: &buf, format: "ndjson"}
+
+	entry := LogEntry{Level: WARN, Component: "api", Message: "slow", Score: 42, IsAnomaly: true}
+	if err := s.Write(entry); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	var rec OutputRecord
+	if err := json.Unmarshal(bytes.TrimSpace(buf.Bytes()), &rec); err != nil {
+		t.Fatalf("output isn't valid single-line JSON: %v (%q)", err, buf.String())
+	}
+	if rec.Level != "WARN" || rec.Component != "api" || rec.Score != 42 || !rec.IsAnomaly {
+		t.Errorf("decoded record = %+v, want level=WARN component=api score=42 is_anomaly=true", rec)
+	}
+	if strings.Count(buf.String(), "\n") != 1 {
+		t.Errorf("ndjson output must be exactly one line, got %q", buf.String())
+	}
+}
+
+func TestWriterSinkDriftJSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	s := &writerSink{w: &buf, format: "json"}
+
+	event := DriftEvent{Component: "api", Pattern: "error", Observed: 5, Expected: 1, StdDev: 0.5, ZScore: 8}
+	if err := s.WriteDrift(event); err != nil {
+		t.Fatalf("WriteDrift: %v", err)
+	}
+
+	var rec DriftRecord
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("output isn't valid JSON: %v (%q)", err, buf.String())
+	}
+	if rec.Type != "drift" || rec.Component != "api" || rec.Pattern != "error" || rec.ZScore != 8 {
+		t.Errorf("decoded record = %+v, want type=drift component=api pattern=error z_score=8", rec)
+	}
+}
+
+func TestRotatingFileSinkRotatesOnSize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.log")
+
+	// maxBytes small enough that a single write forces the next one to rotate.
+	sink, err := newRotatingFileSink(path, "text", 10, 2)
+	if err != nil {
+		t.Fatalf("newRotatingFileSink: %v", err)
+	}
+	defer sink.Close()
+
+	if err := sink.Write(LogEntry{Level: INFO, Message: "first message is long enough"}); err != nil {
+		t.Fatalf("Write 1: %v", err)
+	}
+	if err := sink.Write(LogEntry{Level: INFO, Message: "second"}); err != nil {
+		t.Fatalf("Write 2: %v", err)
+	}
+
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Fatalf("expected %s.1 to exist after rotation: %v", path, err)
+	}
+
+	rotated, err := os.ReadFile(path + ".1")
+	if err != nil {
+		t.Fatalf("reading %s.1: %v", path, err)
+	}
+	if !strings.Contains(string(rotated), "first message is long enough") {
+		t.Errorf("%s.1 = %q, want it to contain the pre-rotation write", path, rotated)
+	}
+
+	current, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+	if !strings.Contains(string(current), "second") {
+		t.Errorf("%s = %q, want it to contain the post-rotation write", path, current)
+	}
+}
+
+func TestRotatingFileSinkPrunesBeyondMaxFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.log")
+
+	sink, err := newRotatingFileSink(path, "text", 1, 2) // rotate on every write, keep 2 backups
+	if err != nil {
+		t.Fatalf("newRotatingFileSink: %v", err)
+	}
+	defer sink.Close()
+
+	for i := 0; i < 5; i++ {
+		if err := sink.Write(LogEntry{Level: INFO, Message: "line"}); err != nil {
+			t.Fatalf("Write %d: %v", i, err)
+		}
+	}
+
+	for _, suffix := range []string{".1", ".2"} {
+		if _, err := os.Stat(path + suffix); err != nil {
+			t.Errorf("expected %s%s to exist: %v", path, suffix, err)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected %s.3 to not exist (maxFiles=2), stat err = %v", path, err)
+	}
+}
+
+func TestSinkPipelineSubmitAndClose(t *testing.T) {
+	var buf bytes.Buffer
+	pipeline := NewSinkPipeline(&writerSink{w: &buf, format: "text"})
+
+	pipeline.Submit(LogEntry{Level: ERROR, Message: "boom"})
+	pipeline.SubmitDrift(DriftEvent{Component: "api", Pattern: "error"})
+
+	if err := pipeline.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if pipeline.Dropped() != 0 {
+		t.Errorf("Dropped() = %d, want 0", pipeline.Dropped())
+	}
+	if !strings.Contains(buf.String(), "boom") {
+		t.Errorf("output = %q, want it to contain the submitted entry", buf.String())
+	}
+	if !strings.Contains(buf.String(), "DRIFT") {
+		t.Errorf("output = %q, want it to contain the submitted drift event", buf.String())
+	}
+}